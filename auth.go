@@ -0,0 +1,362 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// User is the GORM model backing authentication. Passwords are never stored
+// in plaintext; only the bcrypt hash is persisted.
+type User struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Username     string    `json:"username" gorm:"uniqueIndex;not null"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role" gorm:"default:user"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// sessionData is what we persist per session token, either in Redis or in
+// the signed cookie fallback.
+type sessionData struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+func init() {
+	// gin-contrib/sessions' cookie store gob-encodes session.Values, so
+	// any concrete type stored there must be registered up front.
+	gob.Register(sessionData{})
+}
+
+const (
+	sessionKeyPrefix  = "session:"
+	sessionCookieName = "viduli_session"
+	authContextUser   = "authUser"
+)
+
+var errSessionNotFound = errors.New("session not found")
+
+func sessionTTL() time.Duration {
+	if v := os.Getenv("SESSION_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return 24 * time.Hour
+}
+
+func newSessionToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// createSession issues a new opaque session token. When Redis is available
+// the token maps to sessionData with a TTL; callers fall back to
+// gin-contrib/sessions cookies when cache is nil.
+func createSession(u *User) (string, error) {
+	token, err := newSessionToken()
+	if err != nil {
+		return "", err
+	}
+
+	data := sessionData{UserID: u.ID, Username: u.Username, Role: u.Role}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	if cache != nil {
+		if err := cache.Set(ctx, sessionKeyPrefix+token, payload, sessionTTL()).Err(); err != nil {
+			return "", err
+		}
+		indexKey := userSessionsKey(u.ID)
+		if err := cache.SAdd(ctx, indexKey, token).Err(); err != nil {
+			// best-effort index bookkeeping; the session itself is still
+			// valid without it, so we only log.
+			log.Printf("createSession: failed to index session for revoke-all: %v", err)
+		}
+		// Bound the index's lifetime to the newest session it tracks, so a
+		// user who stops logging in doesn't leave a set of dead tokens
+		// around forever.
+		cache.Expire(ctx, indexKey, sessionTTL())
+	}
+
+	return token, nil
+}
+
+func userSessionsKey(userID uint) string {
+	return "session:user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+// getSession looks up a session token and, when found, slides its
+// expiration forward so active users are not logged out mid-session.
+func getSession(token string) (*sessionData, error) {
+	if cache == nil {
+		return nil, errSessionNotFound
+	}
+
+	val, err := cache.Get(ctx, sessionKeyPrefix+token).Result()
+	if err != nil {
+		return nil, errSessionNotFound
+	}
+
+	var data sessionData
+	if err := json.Unmarshal([]byte(val), &data); err != nil {
+		return nil, err
+	}
+
+	cache.Expire(ctx, sessionKeyPrefix+token, sessionTTL())
+
+	return &data, nil
+}
+
+func revokeSession(token string) {
+	if cache == nil {
+		return
+	}
+	// Look the session up first so we can SREM it from its user's index;
+	// otherwise the index accumulates dead tokens forever.
+	if val, err := cache.Get(ctx, sessionKeyPrefix+token).Result(); err == nil {
+		var data sessionData
+		if err := json.Unmarshal([]byte(val), &data); err == nil {
+			cache.SRem(ctx, userSessionsKey(data.UserID), token)
+		}
+	}
+	cache.Del(ctx, sessionKeyPrefix+token)
+}
+
+// revokeAllSessionsForUser scans the Redis set tracking a user's issued
+// tokens and deletes every one of them, e.g. after a password change.
+func revokeAllSessionsForUser(userID uint) error {
+	if cache == nil {
+		return nil
+	}
+
+	key := userSessionsKey(userID)
+	tokens, err := cache.SMembers(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		cache.Del(ctx, sessionKeyPrefix+token)
+	}
+	return cache.Del(ctx, key).Err()
+}
+
+// sessionMiddleware mounts gin-contrib/sessions with a cookie store so auth
+// keeps working when Redis is unavailable. It is mounted for every request;
+// resolveSession only reads the cookie session when the request carries no
+// valid Redis-backed token.
+func sessionMiddleware() gin.HandlerFunc {
+	secret := os.Getenv("SESSION_SECRET")
+	if secret == "" {
+		secret = "dev-insecure-session-secret"
+	}
+	store := cookie.NewStore([]byte(secret))
+	store.Options(sessions.Options{
+		MaxAge:   int(sessionTTL().Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return sessions.Sessions(sessionCookieName, store)
+}
+
+func sessionTokenFromRequest(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		if strings.HasPrefix(auth, "Bearer ") {
+			return strings.TrimPrefix(auth, "Bearer ")
+		}
+	}
+	if cookie, err := c.Cookie(sessionCookieName + "_token"); err == nil {
+		return cookie
+	}
+	return ""
+}
+
+// resolveSession looks up the caller's session data, preferring the
+// Redis-backed opaque token and falling back to the signed cookie session.
+// It does not touch the handler chain, so callers decide when (or whether)
+// to call c.Next().
+func resolveSession(c *gin.Context) *sessionData {
+	if token := sessionTokenFromRequest(c); token != "" {
+		if data, err := getSession(token); err == nil {
+			return data
+		}
+	}
+
+	sess := sessions.Default(c)
+	if raw := sess.Get("user"); raw != nil {
+		if data, ok := raw.(sessionData); ok {
+			return &data
+		}
+	}
+
+	return nil
+}
+
+// RequireAuth resolves the caller's session and aborts with 401 when
+// neither the Redis-backed token nor the cookie session yields a valid user.
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data := resolveSession(c)
+		if data == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		}
+		c.Set(authContextUser, data)
+		c.Next()
+	}
+}
+
+// RequireRole resolves the caller's session and checks its role itself
+// rather than delegating to RequireAuth, so the downstream handler never
+// runs before the role check has passed.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data := resolveSession(c)
+		if data == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		}
+		if data.Role != role {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+			return
+		}
+		c.Set(authContextUser, data)
+		c.Next()
+	}
+}
+
+func currentUser(c *gin.Context) *sessionData {
+	v, ok := c.Get(authContextUser)
+	if !ok {
+		return nil
+	}
+	data, ok := v.(*sessionData)
+	if !ok {
+		return nil
+	}
+	return data
+}
+
+type registerRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+type loginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func registerUser(c *gin.Context) {
+	var req registerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database unavailable"})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
+		return
+	}
+
+	user := User{Username: req.Username, PasswordHash: string(hash), Role: "user"}
+	if err := db.Create(&user).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "username already taken"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+func loginUser(c *gin.Context) {
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database unavailable"})
+		return
+	}
+
+	var user User
+	if err := db.Where("username = ?", req.Username).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	if cache != nil {
+		token, err := createSession(&user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create session"})
+			return
+		}
+		c.SetCookie(sessionCookieName+"_token", token, int(sessionTTL().Seconds()), "/", "", false, true)
+		c.JSON(http.StatusOK, gin.H{"token": token})
+		return
+	}
+
+	sess := sessions.Default(c)
+	sess.Set("user", sessionData{UserID: user.ID, Username: user.Username, Role: user.Role})
+	if err := sess.Save(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create session"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "logged in"})
+}
+
+func logoutUser(c *gin.Context) {
+	if token := sessionTokenFromRequest(c); token != "" {
+		revokeSession(token)
+		c.SetCookie(sessionCookieName+"_token", "", -1, "/", "", false, true)
+	}
+
+	sess := sessions.Default(c)
+	sess.Clear()
+	_ = sess.Save()
+
+	c.Status(http.StatusNoContent)
+}
+
+func meHandler(c *gin.Context) {
+	data := currentUser(c)
+	if data == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
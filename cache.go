@@ -0,0 +1,78 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	listCacheIndexKey = "items:list:index"
+	listCacheTTL      = 5 * time.Minute
+
+	// itemsListAllCacheKey is the sole cache entry for the legacy /api/items
+	// handler. It ignores query params (db.Find(&items) always returns
+	// everything), so a single fixed key is correct here — hashing the raw
+	// query string would create one unbounded cache entry per distinct
+	// ?arbitrary=param a caller sends.
+	itemsListAllCacheKey = "items:list:all"
+)
+
+// listCacheGroup coalesces concurrent cache misses for the same key so only
+// one DB query fires per key, no matter how many requests arrive for it.
+var listCacheGroup singleflight.Group
+
+var (
+	cacheHitsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "viduli_list_cache_hits_total",
+		Help: "Number of items list-cache hits.",
+	})
+	cacheMissesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "viduli_list_cache_misses_total",
+		Help: "Number of items list-cache misses.",
+	})
+	cacheCoalescedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "viduli_list_cache_coalesced_total",
+		Help: "Number of concurrent list-cache misses coalesced via singleflight.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(cacheHitsTotal, cacheMissesTotal, cacheCoalescedTotal)
+}
+
+// cacheListResult stores the serialized list response under key and records
+// key in the items:list:index set so it can be invalidated in bulk later.
+func cacheListResult(key string, payload []byte) error {
+	pipe := cache.TxPipeline()
+	pipe.Set(ctx, key, payload, listCacheTTL)
+	pipe.SAdd(ctx, listCacheIndexKey, key)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// invalidateListCacheScript atomically deletes every cache key tracked in
+// the index set, then the index itself, so a single write never leaves the
+// index out of sync with the keys it describes.
+var invalidateListCacheScript = redis.NewScript(`
+local keys = redis.call('SMEMBERS', KEYS[1])
+for _, key in ipairs(keys) do
+	redis.call('DEL', key)
+end
+redis.call('DEL', KEYS[1])
+return #keys
+`)
+
+// invalidateListCache flushes every items:list:* cache entry, used whenever
+// a write can affect the result of any list query.
+func invalidateListCache() {
+	if cache == nil {
+		return
+	}
+	if err := invalidateListCacheScript.Run(ctx, cache, []string{listCacheIndexKey}).Err(); err != nil {
+		log.Printf("invalidateListCache: failed to flush list cache: %v", err)
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"strings"
+	"regexp"
+	"strconv"
 	"time"
 
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
@@ -23,50 +26,163 @@ type Item struct {
 	Description string
 }
 
-func ConnectDB() {
-	var dsn string
-
-	// Check if DATABASE_URL is provided (single connection string)
-	dsn = os.Getenv("DATABASE_URL")
-
-	// If not, build DSN from separate env vars
-	if dsn == "" {
-		host := os.Getenv("DB_HOST")
-		user := os.Getenv("DB_USER")
-		password := os.Getenv("DB_PASSWORD")
-		dbname := os.Getenv("DB_NAME")
-		port := os.Getenv("DB_PORT")
-		sslmode := os.Getenv("SSL_MODE")
-
-		if sslmode == "" {
-			sslmode = "disable"
+// Config holds everything needed to open a connection, independent of which
+// SQL dialect backs it. DSN, when set, takes precedence over the individual
+// fields for building the connection string.
+type Config struct {
+	Dialect  string // postgres, mysql, sqlite
+	DSN      string
+	Host     string
+	Port     string
+	User     string
+	Password string
+	DBName   string
+	SSLMode  string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// ConfigFromEnv builds a Config from DB_DIALECT, DATABASE_URL and the
+// individual DB_* environment variables, applying the defaults the service
+// has always shipped with.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Dialect:  os.Getenv("DB_DIALECT"),
+		DSN:      os.Getenv("DATABASE_URL"),
+		Host:     os.Getenv("DB_HOST"),
+		Port:     os.Getenv("DB_PORT"),
+		User:     os.Getenv("DB_USER"),
+		Password: os.Getenv("DB_PASSWORD"),
+		DBName:   os.Getenv("DB_NAME"),
+		SSLMode:  os.Getenv("SSL_MODE"),
+
+		MaxOpenConns:    envInt("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:    envInt("DB_MAX_IDLE_CONNS", 5),
+		ConnMaxLifetime: envDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+	}
+
+	if cfg.Dialect == "" {
+		cfg.Dialect = "postgres"
+	}
+	if cfg.SSLMode == "" {
+		cfg.SSLMode = "disable"
+	}
+
+	return cfg
+}
+
+func envInt(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
 		}
-		if port == "" {
-			port = "5432"
+	}
+	return def
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
 		}
+	}
+	return def
+}
 
-		dsn = fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-			host, user, password, dbname, port, sslmode)
+func withDefault(v, def string) string {
+	if v == "" {
+		return def
 	}
+	return v
+}
 
-	// Fallback to localhost for local dev
-	if dsn == "" {
-		dsn = "host=localhost user=postgres password=postgres dbname=postgres port=5432 sslmode=disable"
+// dsn builds the dialect-specific connection string, preferring an explicit
+// DSN/DATABASE_URL when one is provided.
+func (c Config) dsn() string {
+	if c.DSN != "" {
+		return c.DSN
 	}
 
-	log.Printf("Connecting to database with DSN: %s", maskPassword(dsn))
+	switch c.Dialect {
+	case "mysql":
+		return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			c.User, c.Password, withDefault(c.Host, "localhost"), withDefault(c.Port, "3306"), c.DBName)
+	case "sqlite":
+		return withDefault(c.DBName, "file::memory:?cache=shared")
+	default:
+		return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+			withDefault(c.Host, "localhost"), withDefault(c.User, "postgres"), c.Password,
+			withDefault(c.DBName, "postgres"),
+			withDefault(c.Port, "5432"), c.SSLMode)
+	}
+}
 
-	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+var credentialPattern = regexp.MustCompile(`(password=)[^\s]*|(://[^:]+:)[^@]*(@)|(:)[^:@/]+(@)`)
+
+// maskDSN redacts credentials from a DSN so it's safe to log, across all
+// three supported dialects.
+func maskDSN(dsn string) string {
+	return credentialPattern.ReplaceAllString(dsn, "${1}${2}${4}***${3}${5}")
+}
+
+// ResolvedDSN returns the dialect-specific connection string Open would use,
+// for callers that need the raw DSN outside of Open (e.g. a dedicated
+// pq.Listener connection).
+func (c Config) ResolvedDSN() string {
+	return c.dsn()
+}
+
+// Open opens a GORM connection for the dialect named in cfg, applies the
+// configured connection-pool limits, and stores the handle in DB.
+func Open(cfg Config) (*gorm.DB, error) {
+	dsn := cfg.dsn()
+	log.Printf("database: connecting with dialect=%s dsn=%s", cfg.Dialect, maskDSN(dsn))
+
+	var dialector gorm.Dialector
+	switch cfg.Dialect {
+	case "mysql":
+		dialector = mysql.Open(dsn)
+	case "sqlite":
+		dialector = sqlite.Open(dsn)
+	default:
+		dialector = postgres.Open(dsn)
+	}
+
+	gormDB, err := gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		return nil, fmt.Errorf("database: failed to open %s connection: %w", cfg.Dialect, err)
 	}
 
-	DB.AutoMigrate(&Item{})
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("database: failed to get underlying sql.DB: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	DB = gormDB
+	return DB, nil
+}
+
+// Migrate runs AutoMigrate for the given models against the connection
+// opened by Open, so new models can be registered from one place.
+func Migrate(models ...interface{}) error {
+	if DB == nil {
+		return fmt.Errorf("database: Migrate called before Open")
+	}
+	return DB.AutoMigrate(models...)
 }
 
-func maskPassword(dsn string) string {
-	// Simple masking for logging - don't expose password
-	// Just for debug purposes
-	return strings.Replace(dsn, "password=", "password=***", 1)
+// ConnectDB preserves the original package-level entry point for callers
+// that haven't migrated to Open/ConfigFromEnv yet.
+func ConnectDB() {
+	if _, err := Open(ConfigFromEnv()); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	if err := Migrate(&Item{}); err != nil {
+		log.Printf("AutoMigrate warning: %v", err)
+	}
 }
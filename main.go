@@ -3,11 +3,8 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"log"
-	"net"
 	"net/http"
-	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -15,8 +12,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 	"github.com/joho/godotenv"
-	"gorm.io/driver/postgres"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gorm.io/gorm"
+
+	"github.com/shivamchaubey027/viduli-connectivity/database"
 )
 
 var (
@@ -27,115 +26,29 @@ var (
 
 type Item struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
-	Name        string    `json:"name"`
+	Name        string    `json:"name" binding:"required,notblank"`
 	Description string    `json:"description"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-func connectDB() error {
-	dsn := os.Getenv("DATABASE_URL")
-	var host, port, password string
-
-	if dsn == "" {
-		host = os.Getenv("DB_HOST")
-		if host == "" {
-			host = "localhost"
-		}
-		port = os.Getenv("DB_PORT")
-		if port == "" {
-			port = "5432"
-		}
-		user := os.Getenv("DB_USER")
-		if user == "" {
-			user = "postgres"
-		}
-		password = os.Getenv("DB_PASSWORD")
-		dbname := os.Getenv("DB_NAME")
-		if dbname == "" {
-			dbname = "postgres"
-		}
-		sslmode := os.Getenv("SSL_MODE")
-		if sslmode == "" {
-			sslmode = "disable"
-		}
-		dsn = "host=" + host +
-			" user=" + user +
-			" password=" + password +
-			" dbname=" + dbname +
-			" port=" + port +
-			" sslmode=" + sslmode
-	} else {
-		// parse DATABASE_URL for host/port/password for diagnostics
-		if u, err := url.Parse(dsn); err == nil {
-			if h := u.Hostname(); h != "" {
-				host = h
-			}
-			if p := u.Port(); p != "" {
-				port = p
-			} else {
-				port = "5432"
-			}
-			if u.User != nil {
-				if pw, ok := u.User.Password(); ok {
-					password = pw
-				}
-			}
-		}
-		if host == "" {
-			host = "localhost"
-		}
-		if port == "" {
-			port = "5432"
-		}
-	}
-
-	// sanitized DSN for logs (don't print password)
-	safeDSN := strings.ReplaceAll(dsn, "password="+password, "password=REDACTED")
-	log.Printf("connectDB: attempting with DSN: %s", safeDSN)
-	addr := net.JoinHostPort(host, port)
-
-	// raw TCP check
-	dialErr := func() error {
-		conn, err := net.DialTimeout("tcp", addr, 3*time.Second)
-		if err != nil {
-			return err
-		}
-		_ = conn.Close()
-		return nil
-	}()
-
-	if dialErr != nil {
-		log.Printf("connectDB: RAW TCP connect to %s failed: %v", addr, dialErr)
-	} else {
-		log.Printf("connectDB: RAW TCP connect to %s succeeded", addr)
-	}
-
-	// try GORM open + ping with retries and clear logging
+// connectDB opens the database described by cfg through database.Open,
+// retrying a few times since the DB may still be starting up (e.g. in
+// docker-compose).
+func connectDB(cfg database.Config) error {
 	var openErr error
 	for i := 1; i <= 3; i++ {
-		db, openErr = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-		if openErr == nil {
-			sqlDB, derr := db.DB()
-			if derr == nil {
-				if pingErr := sqlDB.Ping(); pingErr == nil {
-					log.Printf("connectDB: connected to DB on attempt %d", i)
-					return nil
-				} else {
-					openErr = pingErr
-				}
-			} else {
-				openErr = derr
-			}
+		gormDB, err := database.Open(cfg)
+		if err == nil {
+			db = gormDB
+			log.Printf("connectDB: connected to DB on attempt %d", i)
+			return nil
 		}
-		log.Printf("connectDB: gorm attempt %d failed: %v", i, openErr)
+		openErr = err
+		log.Printf("connectDB: attempt %d failed: %v", i, openErr)
 		time.Sleep(time.Duration(i) * time.Second)
 	}
 
-	// helpful guidance in error
-	if dialErr != nil {
-		return fmt.Errorf("gorm/connect failed: %w; raw tcp error: %v", openErr, dialErr)
-	}
 	return openErr
 }
 
@@ -163,14 +76,22 @@ func connectCache() (*redis.Client, error) {
 func main() {
 	_ = godotenv.Load() // optional .env
 
+	if os.Getenv("APP_ENV") == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
 	// DB
-	if err := connectDB(); err != nil {
+	dbCfg := database.ConfigFromEnv()
+	if err := connectDB(dbCfg); err != nil {
 		log.Println("DB not available, running without persistent DB:", err)
 	} else {
 		log.Println("Connected to DB, running migrations")
-		if err := db.AutoMigrate(&Item{}); err != nil {
+		if err := database.Migrate(&Item{}, &User{}); err != nil {
 			log.Printf("AutoMigrate warning: %v", err)
 		}
+		if dbCfg.Dialect == "postgres" {
+			go startItemChangeListener(dbCfg.ResolvedDSN())
+		}
 	}
 
 	// Redis
@@ -183,7 +104,16 @@ func main() {
 		log.Println("Connected to Redis")
 	}
 
-	router := gin.Default()
+	// gin.New() (not gin.Default()) so requestLoggingMiddleware is the only
+	// per-request logger; gin.Default() would also install its own plain
+	// text Logger() and double-log every request.
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(requestLoggingMiddleware())
+	router.Use(sessionMiddleware())
+
+	router.GET("/healthz", healthzHandler)
+	router.GET("/readyz", readyzHandler)
 
 	// serve static assets if present
 	router.Static("/assets", "./public/assets")
@@ -202,33 +132,86 @@ func main() {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Not found"})
 	})
 
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.StaticFile("/openapi.json", "./docs/openapi.json")
+	router.GET("/docs", docsHandler)
+
+	auth := router.Group("/api/auth")
+	{
+		auth.POST("/register", registerUser)
+		auth.POST("/login", loginUser)
+		auth.POST("/logout", logoutUser)
+		auth.GET("/me", RequireAuth(), meHandler)
+	}
+
+	// /api is kept for backward compat; new consumers should use /api/v1.
 	api := router.Group("/api")
 	{
 		api.GET("/items", getItems)
-		api.POST("/items", createItem)
+		api.POST("/items", RequireAuth(), createItem)
 		api.GET("/items/:id", getItem)
-		api.PUT("/items/:id", updateItem)
-		api.DELETE("/items/:id", deleteItem)
+		api.PUT("/items/:id", RequireAuth(), updateItem)
+		api.DELETE("/items/:id", RequireRole("admin"), deleteItem)
 	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	v1 := router.Group("/api/v1")
+	{
+		v1.GET("/items", listItemsV1)
+		v1.POST("/items", RequireAuth(), createItem)
+		v1.GET("/items/:id", getItem)
+		v1.PUT("/items/:id", RequireAuth(), updateItem)
+		v1.DELETE("/items/:id", RequireRole("admin"), deleteItem)
 	}
-	log.Printf("Server starting on :%s", port)
-	if err := router.Run(":" + port); err != nil {
+
+	if err := runServer(router); err != nil {
 		log.Fatalf("server failed: %v", err)
 	}
 }
 
 func getItems(c *gin.Context) {
-	var items []Item
-	if db != nil {
+	if db == nil {
+		c.JSON(http.StatusOK, []Item{})
+		return
+	}
+
+	if cache == nil {
+		var items []Item
 		db.Find(&items)
 		c.JSON(http.StatusOK, items)
 		return
 	}
-	c.JSON(http.StatusOK, items)
+
+	key := itemsListAllCacheKey
+
+	if val, err := cache.Get(ctx, key).Result(); err == nil && val != "" {
+		cacheHitsTotal.Inc()
+		c.Data(http.StatusOK, "application/json", []byte(val))
+		return
+	}
+	cacheMissesTotal.Inc()
+
+	v, err, shared := listCacheGroup.Do(key, func() (interface{}, error) {
+		var items []Item
+		db.Find(&items)
+
+		payload, err := json.Marshal(items)
+		if err != nil {
+			return nil, err
+		}
+		if err := cacheListResult(key, payload); err != nil {
+			log.Printf("getItems: failed to cache list result: %v", err)
+		}
+		return payload, nil
+	})
+	if shared {
+		cacheCoalescedTotal.Inc()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load items"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", v.([]byte))
 }
 
 func createItem(c *gin.Context) {
@@ -245,6 +228,7 @@ func createItem(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "DB create failed"})
 			return
 		}
+		invalidateListCache()
 		c.JSON(http.StatusCreated, item)
 		return
 	}
@@ -307,6 +291,7 @@ func updateItem(c *gin.Context) {
 	db.Save(&item)
 	if cache != nil {
 		cache.Del(ctx, "item:"+id)
+		invalidateListCache()
 	}
 	c.JSON(http.StatusOK, item)
 }
@@ -328,6 +313,7 @@ func deleteItem(c *gin.Context) {
 	db.Delete(&item)
 	if cache != nil {
 		cache.Del(ctx, "item:"+id)
+		invalidateListCache()
 	}
 	c.Status(http.StatusNoContent)
 }
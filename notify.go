@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+const itemsChangedChannel = "items_changed"
+
+// itemChangeNotification is the payload sent through pg_notify and decoded
+// by startItemChangeListener on every replica of this service.
+type itemChangeNotification struct {
+	ID uint   `json:"id"`
+	Op string `json:"op"`
+}
+
+// AfterCreate, AfterUpdate and AfterDelete fire pg_notify('items_changed', ...)
+// so other replicas sharing this DB/Redis pair can invalidate their caches
+// without polling.
+func (i *Item) AfterCreate(tx *gorm.DB) error {
+	return notifyItemChanged(tx, i.ID, "insert")
+}
+
+func (i *Item) AfterUpdate(tx *gorm.DB) error {
+	return notifyItemChanged(tx, i.ID, "update")
+}
+
+func (i *Item) AfterDelete(tx *gorm.DB) error {
+	return notifyItemChanged(tx, i.ID, "delete")
+}
+
+func notifyItemChanged(tx *gorm.DB, id uint, op string) error {
+	if tx.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	payload, err := json.Marshal(itemChangeNotification{ID: id, Op: op})
+	if err != nil {
+		return err
+	}
+	return tx.Exec("SELECT pg_notify('"+itemsChangedChannel+"', ?)", string(payload)).Error
+}
+
+// startItemChangeListener opens a dedicated pq.Listener on dsn and
+// invalidates the matching Redis entries whenever a NOTIFY arrives on
+// items_changed. It blocks, so callers should run it in its own goroutine.
+func startItemChangeListener(dsn string) {
+	listener := pq.NewListener(dsn, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("itemChangeListener: connection event: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(itemsChangedChannel); err != nil {
+		log.Printf("itemChangeListener: failed to listen on %s: %v", itemsChangedChannel, err)
+		return
+	}
+	log.Printf("itemChangeListener: listening on %s", itemsChangedChannel)
+
+	for {
+		select {
+		case notification := <-listener.Notify:
+			if notification == nil {
+				continue
+			}
+			handleItemChangeNotification(notification.Extra)
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
+
+func handleItemChangeNotification(payload string) {
+	var n itemChangeNotification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		log.Printf("itemChangeListener: failed to decode payload %q: %v", payload, err)
+		return
+	}
+
+	if cache == nil {
+		return
+	}
+
+	cache.Del(ctx, "item:"+strconv.FormatUint(uint64(n.ID), 10))
+	invalidateListCache()
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func listenPort() string {
+	p := os.Getenv("PORT")
+	if p == "" {
+		p = "8080"
+	}
+	return p
+}
+
+func shutdownGracePeriod() time.Duration {
+	if v := os.Getenv("SHUTDOWN_GRACE_PERIOD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return 15 * time.Second
+}
+
+// healthzHandler is a liveness probe: if the process can answer HTTP at all,
+// it's alive. It never checks dependencies.
+func healthzHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// readyzHandler is a readiness probe. The rest of main() is built to
+// degrade gracefully when the DB or Redis are absent at startup, so an
+// "unavailable" dependency isn't itself a readiness failure — only a
+// dependency that was established and has since stopped responding pulls
+// this instance out of rotation.
+func readyzHandler(c *gin.Context) {
+	checks := gin.H{}
+	ready := true
+
+	if db == nil {
+		checks["database"] = "unavailable"
+	} else if sqlDB, err := db.DB(); err != nil || sqlDB.Ping() != nil {
+		ready = false
+		checks["database"] = "down"
+	} else {
+		checks["database"] = "ok"
+	}
+
+	if cache == nil {
+		checks["redis"] = "unavailable"
+	} else if err := cache.Ping(ctx).Err(); err != nil {
+		ready = false
+		checks["redis"] = "down"
+	} else {
+		checks["redis"] = "ok"
+	}
+
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, checks)
+}
+
+// runServer serves router behind an http.Server and blocks until SIGINT or
+// SIGTERM is received, at which point it drains in-flight requests within
+// shutdownGracePeriod and closes the DB/Redis clients.
+func runServer(router *gin.Engine) error {
+	srv := &http.Server{
+		Addr:    ":" + listenPort(),
+		Handler: router,
+	}
+
+	shutdownSignal, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server starting on %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-shutdownSignal.Done():
+	}
+
+	log.Println("shutdown signal received, draining connections")
+	grace, cancel := context.WithTimeout(context.Background(), shutdownGracePeriod())
+	defer cancel()
+
+	err := srv.Shutdown(grace)
+	closeResources()
+	return err
+}
+
+func closeResources() {
+	if db != nil {
+		if sqlDB, err := db.DB(); err == nil {
+			if err := sqlDB.Close(); err != nil {
+				log.Printf("closeResources: failed to close DB: %v", err)
+			}
+		}
+	}
+	if cache != nil {
+		if err := cache.Close(); err != nil {
+			log.Printf("closeResources: failed to close Redis client: %v", err)
+		}
+	}
+}
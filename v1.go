@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// itemListQuery binds the pagination, sorting and search query params
+// accepted by GET /api/v1/items.
+type itemListQuery struct {
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+	Sort     string `form:"sort"`
+	Q        string `form:"q"`
+}
+
+// itemSortColumns whitelists the columns GET /api/v1/items can sort by, so
+// the ?sort= value can never be used to inject arbitrary SQL.
+var itemSortColumns = map[string]string{
+	"id":          "id",
+	"name":        "name",
+	"description": "description",
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+}
+
+// itemListEnvelope is the {data, page, page_size, total} response shape
+// returned by the v1 items listing.
+type itemListEnvelope struct {
+	Data     []Item `json:"data"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+	Total    int64  `json:"total"`
+}
+
+// listItemsV1 backs GET /api/v1/items: paginated, sortable, and searchable
+// by name/description via ?q=. Unlike the legacy /api/items handler this
+// always returns the envelope shape rather than a bare array.
+func listItemsV1(c *gin.Context) {
+	var q itemListQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = defaultPageSize
+	}
+	if q.PageSize > maxPageSize {
+		q.PageSize = maxPageSize
+	}
+
+	if db == nil {
+		c.JSON(http.StatusOK, itemListEnvelope{Data: []Item{}, Page: q.Page, PageSize: q.PageSize})
+		return
+	}
+
+	query := db.Model(&Item{})
+	if term := strings.TrimSpace(q.Q); term != "" {
+		// LOWER(...) LIKE LOWER(?) is case-insensitive on all three
+		// supported dialects; ILIKE is Postgres-only and errors on
+		// SQLite/MySQL.
+		like := "%" + strings.ToLower(term) + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ?", like, like)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count items"})
+		return
+	}
+
+	for _, field := range strings.Split(q.Sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		desc := strings.HasPrefix(field, "-")
+		column, ok := itemSortColumns[strings.TrimPrefix(field, "-")]
+		if !ok {
+			continue
+		}
+		if desc {
+			column += " DESC"
+		}
+		query = query.Order(column)
+	}
+
+	var items []Item
+	offset := (q.Page - 1) * q.PageSize
+	if err := query.Limit(q.PageSize).Offset(offset).Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load items"})
+		return
+	}
+
+	c.JSON(http.StatusOK, itemListEnvelope{
+		Data:     items,
+		Page:     q.Page,
+		PageSize: q.PageSize,
+		Total:    total,
+	})
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// init registers the "notblank" validator so binding:"required,notblank"
+// rejects fields that are empty or whitespace-only, not just missing.
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		_ = v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+			return strings.TrimSpace(fl.Field().String()) != ""
+		})
+	}
+}